@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing adds tracing to requests if the incoming request is sampled or
+// sampling is requested. It extracts and propagates the W3C traceparent
+// header from an inbound request, so a caller that is already part of a
+// distributed trace has its spans attached to that trace instead of a new
+// one minted by this handler.
+func WithTracing(handler http.Handler, tp oteltrace.TracerProvider) http.Handler {
+	opts := []otelhttp.Option{
+		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
+		otelhttp.WithTracerProvider(tp),
+	}
+	return otelhttp.NewHandler(handler, "KubernetesAPI", opts...)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/filters"
+	tracing "k8s.io/component-base/tracing"
+	tracingapi "k8s.io/component-base/tracing/api/v1"
+)
+
+// TracingOptions contain configuration options for distributed tracing.
+type TracingOptions struct {
+	// ConfigFile is the file path for the TracingConfiguration manifest this apiserver will load.
+	ConfigFile string
+}
+
+// NewTracingOptions creates a new instance of TracingOptions.
+func NewTracingOptions() *TracingOptions {
+	return &TracingOptions{}
+}
+
+// AddFlags adds flags related to distributed tracing to a specified FlagSet.
+func (o *TracingOptions) AddFlags(fs *pflag.FlagSet) {
+	if o == nil {
+		return
+	}
+	fs.StringVar(&o.ConfigFile, "tracing-config-file", o.ConfigFile,
+		"File with apiserver tracing configuration.")
+}
+
+// ApplyTo fills up Tracing config with options and the shared TracerProvider.
+func (o *TracingOptions) ApplyTo(c *server.Config) error {
+	if o == nil || o.ConfigFile == "" {
+		return nil
+	}
+
+	tc, err := o.loadConfiguration()
+	if err != nil {
+		return fmt.Errorf("failed to load tracing configuration: %w", err)
+	}
+
+	// NewProvider installs the W3C propagator globally before returning, so
+	// the WithTracing filter wired in below -- whenever BuildHandlerChainFunc
+	// is actually invoked to construct the server's handler -- picks it up.
+	provider, err := tracing.NewProvider(context.Background(), "kube-apiserver", tc)
+	if err != nil {
+		return fmt.Errorf("failed to create tracer provider: %w", err)
+	}
+	c.TracerProvider = provider
+
+	delegate := c.BuildHandlerChainFunc
+	c.BuildHandlerChainFunc = func(apiHandler http.Handler, cfg *server.Config) http.Handler {
+		return filters.WithTracing(delegate(apiHandler, cfg), provider)
+	}
+	return nil
+}
+
+// loadConfiguration reads and defaults the TracingConfiguration named by ConfigFile.
+func (o *TracingOptions) loadConfiguration() (*tracingapi.TracingConfiguration, error) {
+	data, err := ioutil.ReadFile(o.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	tc := &tracingapi.TracingConfiguration{}
+	if err := yaml.Unmarshal(data, tc); err != nil {
+		return nil, fmt.Errorf("unable to decode tracing configuration: %w", err)
+	}
+	tracingapi.SetDefaults_TracingConfiguration(tc)
+	return tc, nil
+}
+
+// Validate verifies flags passed to TracingOptions.
+func (o *TracingOptions) Validate() []error {
+	if o == nil || o.ConfigFile == "" {
+		return nil
+	}
+	var errs []error
+	if _, err := o.loadConfiguration(); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
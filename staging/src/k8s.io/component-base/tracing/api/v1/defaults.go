@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import "k8s.io/utils/pointer"
+
+const (
+	// ProtocolGRPC reports traces to the collector over OTLP/gRPC. This is the default.
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP reports traces to the collector over OTLP/HTTP, as protobuf POSTed to /v1/traces.
+	ProtocolHTTP = "http/protobuf"
+)
+
+// defaultEndpoints maps each supported protocol to the port it conventionally
+// listens on, so an unset endpoint doesn't dial the wrong transport's port.
+var defaultEndpoints = map[string]string{
+	ProtocolGRPC: "localhost:4317",
+	ProtocolHTTP: "localhost:4318",
+}
+
+// SetDefaults_TracingConfiguration sets the default values for tracing configuration.
+func SetDefaults_TracingConfiguration(obj *TracingConfiguration) {
+	if obj.Protocol == nil {
+		obj.Protocol = pointer.String(ProtocolGRPC)
+	}
+	if obj.Endpoint == nil {
+		obj.Endpoint = pointer.String(defaultEndpoints[*obj.Protocol])
+	}
+	if obj.SamplingRatePerMillion == nil {
+		obj.SamplingRatePerMillion = pointer.Int32(0)
+	}
+}
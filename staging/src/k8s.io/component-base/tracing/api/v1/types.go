@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// TracingConfiguration provides versioned configuration for OpenTelemetry tracing clients.
+type TracingConfiguration struct {
+	// Endpoint of the collector this component will report traces to.
+	// The connection is insecure unless TLS is set.
+	// Recommended is unset, and endpoint is the OTLP default for Protocol:
+	// localhost:4317 for grpc, localhost:4318 for http/protobuf.
+	// +optional
+	Endpoint *string `json:"endpoint,omitempty"`
+
+	// SamplingRatePerMillion is the number of samples to collect per million spans.
+	// Recommended is unset. If unset, sampler respects its parent span's sampling
+	// rate, but otherwise never samples.
+	// +optional
+	SamplingRatePerMillion *int32 `json:"samplingRatePerMillion,omitempty"`
+
+	// Protocol is the OTLP transport used to report traces to the collector
+	// named by Endpoint. Supported values are "grpc" and "http/protobuf".
+	// Recommended is unset, which defaults to "grpc".
+	// +optional
+	Protocol *string `json:"protocol,omitempty"`
+
+	// TLS holds the client TLS configuration used to connect to Endpoint.
+	// Recommended is unset, which dials Endpoint without TLS.
+	// +optional
+	TLS *TLSConfiguration `json:"tls,omitempty"`
+
+	// Headers are additional headers attached to every export request, e.g.
+	// an Authorization header for collectors that require authentication.
+	// Recommended is unset.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// TLSConfiguration describes the TLS settings used to dial the collector
+// named by TracingConfiguration.Endpoint.
+type TLSConfiguration struct {
+	// CAFile is the path to a PEM-encoded CA bundle used to verify the
+	// collector's certificate. Recommended is unset, which uses the host's
+	// root CA set.
+	// +optional
+	CAFile *string `json:"caFile,omitempty"`
+
+	// CertFile is the path to a PEM-encoded client certificate, for
+	// collectors that require mutual TLS.
+	// +optional
+	CertFile *string `json:"certFile,omitempty"`
+
+	// KeyFile is the path to the PEM-encoded private key matching CertFile.
+	// +optional
+	KeyFile *string `json:"keyFile,omitempty"`
+
+	// InsecureSkipVerify disables verification of the collector's
+	// certificate chain and host name. Not recommended outside of testing.
+	// +optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
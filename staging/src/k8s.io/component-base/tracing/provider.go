@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+
+	certutil "k8s.io/client-go/util/cert"
+	tracingapi "k8s.io/component-base/tracing/api/v1"
+)
+
+// NewProvider creates a TracerProvider that exports spans described by tc to
+// the configured collector, and installs the W3C tracecontext propagator as
+// the process-wide default so inbound traceparent headers are honored.
+//
+// If tc is nil or names no endpoint, a no-op TracerProvider is returned:
+// callers still get a valid provider, but it never exports anything.
+func NewProvider(ctx context.Context, serviceName string, tc *tracingapi.TracingConfiguration) (*sdktrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if tc == nil || tc.Endpoint == nil || *tc.Endpoint == "" {
+		return sdktrace.NewTracerProvider(), nil
+	}
+
+	exporter, err := newExporter(ctx, tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	resource, err := sdkresource.New(ctx, sdkresource.WithAttributes(
+		semconv.ServiceNameKey.String(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	sampler := sdktrace.ParentBased(samplerFromConfig(tc))
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource),
+		sdktrace.WithSampler(sampler),
+	), nil
+}
+
+// samplerFromConfig translates SamplingRatePerMillion into a root sampler.
+func samplerFromConfig(tc *tracingapi.TracingConfiguration) sdktrace.Sampler {
+	if tc.SamplingRatePerMillion == nil || *tc.SamplingRatePerMillion <= 0 {
+		return sdktrace.NeverSample()
+	}
+	return sdktrace.TraceIDRatioBased(float64(*tc.SamplingRatePerMillion) / 1_000_000)
+}
+
+// newExporter builds the OTLP exporter named by tc.Protocol, defaulting to
+// OTLP/gRPC when unset.
+func newExporter(ctx context.Context, tc *tracingapi.TracingConfiguration) (sdktrace.SpanExporter, error) {
+	protocol := tracingapi.ProtocolGRPC
+	if tc.Protocol != nil && *tc.Protocol != "" {
+		protocol = *tc.Protocol
+	}
+
+	switch protocol {
+	case tracingapi.ProtocolGRPC:
+		opts, err := grpcDialOptions(tc)
+		if err != nil {
+			return nil, err
+		}
+		return otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+	case tracingapi.ProtocolHTTP:
+		opts, err := httpOptions(tc)
+		if err != nil {
+			return nil, err
+		}
+		return otlptrace.New(ctx, otlptracehttp.NewClient(opts...))
+	default:
+		return nil, fmt.Errorf("unsupported tracing protocol %q", protocol)
+	}
+}
+
+// grpcDialOptions translates tc into options for the OTLP/gRPC exporter,
+// including TLS and header configuration when present.
+func grpcDialOptions(tc *tracingapi.TracingConfiguration) ([]otlptracegrpc.Option, error) {
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(*tc.Endpoint),
+	}
+
+	if tc.TLS == nil {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		tlsConfig, err := tlsConfigFromConfig(tc.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	if len(tc.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(tc.Headers))
+	}
+
+	return opts, nil
+}
+
+// httpOptions translates tc into options for the OTLP/HTTP exporter,
+// including TLS and header configuration when present.
+func httpOptions(tc *tracingapi.TracingConfiguration) ([]otlptracehttp.Option, error) {
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(*tc.Endpoint),
+	}
+
+	if tc.TLS == nil {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	} else {
+		tlsConfig, err := tlsConfigFromConfig(tc.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	if len(tc.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(tc.Headers))
+	}
+
+	return opts, nil
+}
+
+// tlsConfigFromConfig builds a *tls.Config from a TLSConfiguration, loading
+// the CA bundle and, if present, the client certificate from disk.
+func tlsConfigFromConfig(tc *tracingapi.TLSConfiguration) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if tc.InsecureSkipVerify != nil {
+		tlsConfig.InsecureSkipVerify = *tc.InsecureSkipVerify
+	}
+
+	if tc.CAFile != nil && *tc.CAFile != "" {
+		pool, err := certutil.NewPool(*tc.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tracing CA file %q: %w", *tc.CAFile, err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	haveCert := tc.CertFile != nil && *tc.CertFile != ""
+	haveKey := tc.KeyFile != nil && *tc.KeyFile != ""
+	switch {
+	case haveCert != haveKey:
+		return nil, fmt.Errorf("tracing TLS certFile and keyFile must be set together")
+	case haveCert:
+		cert, err := tls.LoadX509KeyPair(*tc.CertFile, *tc.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tracing client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -18,10 +18,15 @@ package tracing
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"sync"
@@ -32,14 +37,23 @@ import (
 	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
 	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
 	genericfeatures "k8s.io/apiserver/pkg/features"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	kubeapiservertesting "k8s.io/kubernetes/cmd/kube-apiserver/app/testing"
 	"k8s.io/kubernetes/test/integration/framework"
@@ -149,15 +163,575 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 		t.Fatal(err)
 	}
 
-	for _, tc := range []struct {
-		desc          string
-		apiCall       func(*client.Clientset) error
-		expectedTrace []*spanExpectation
-	}{
+	for _, tc := range apiServerTracingTestCases(clientSet) {
+		t.Run(tc.desc, func(t *testing.T) {
+			fakeServer.resetExpectations(tc.expectedTrace)
+
+			// Make our call to the API server
+			if err := tc.apiCall(clientSet); err != nil {
+				t.Fatal(err)
+			}
+
+			// Wait for a span to be recorded from our request
+			select {
+			case <-fakeServer.traceFound:
+			case <-time.After(30 * time.Second):
+				t.Fatal("Timed out waiting for trace")
+			}
+		})
+	}
+}
+
+// injectedTraceID and injectedSpanID are a fixed, well-formed W3C trace
+// context that testTraceparentRoundTripper injects into every outgoing
+// request, so the test can assert that the API server honors an inbound
+// traceparent header instead of always minting a root span.
+const (
+	injectedTraceID = "0102030405060708090a0b0c0d0e0f10"
+	injectedSpanID  = "1112131415161718"
+)
+
+// testTraceparentRoundTripper wraps a RoundTripper and stamps every request
+// with a known W3C traceparent header, simulating a caller that is already
+// part of a distributed trace.
+type testTraceparentRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (t *testTraceparentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", injectedTraceID, injectedSpanID))
+	return t.rt.RoundTrip(req)
+}
+
+// TestAPIServerTracingPropagation asserts that the API server's HTTP handler
+// chain honors an inbound W3C traceparent header: the KubernetesAPI span and
+// the etcd span it triggers should both be part of the caller's trace,
+// rather than the root of a brand new one.
+func TestAPIServerTracingPropagation(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, genericfeatures.APIServerTracing, true)()
+	// Listen for traces from the API Server before starting it, so the
+	// API Server will successfully connect right away during the test.
+	listener, err := net.Listen("tcp", "localhost:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write the configuration for tracing to a file
+	tracingConfigFile, err := os.CreateTemp("", "tracing-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tracingConfigFile.Name())
+
+	if err := os.WriteFile(tracingConfigFile.Name(), []byte(fmt.Sprintf(`
+apiVersion: apiserver.config.k8s.io/v1alpha1
+kind: TracingConfiguration
+samplingRatePerMillion: 1000000
+endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	fakeServer := &traceServer{t: t}
+	fakeServer.resetExpectations([]*spanExpectation{})
+	traceservice.RegisterTraceServiceServer(srv, fakeServer)
+
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	// Start the API Server with our tracing configuration
+	testServer := kubeapiservertesting.StartTestServerOrDie(t,
+		kubeapiservertesting.NewDefaultTestServerOptions(),
+		[]string{"--tracing-config-file=" + tracingConfigFile.Name()},
+		framework.SharedEtcd(),
+	)
+	defer testServer.TearDownFn()
+
+	// Inject a known traceparent on every request this client makes, as if
+	// it were already participating in a trace started upstream.
+	clientConfig := rest.CopyConfig(testServer.ClientConfig)
+	clientConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return &testTraceparentRoundTripper{rt: rt}
+	}
+	clientSet, err := client.NewForConfig(clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeServer.resetExpectations([]*spanExpectation{
+		{
+			name:          "KubernetesAPI",
+			parentTraceID: injectedTraceID,
+		},
+		{
+			name:          "etcdserverpb.KV/Txn",
+			parentTraceID: injectedTraceID,
+			events:        []string{"message"},
+		},
+	})
+
+	_, err = clientSet.CoreV1().Nodes().Create(context.Background(),
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "propagated"}}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fakeServer.traceFound:
+	case <-time.After(30 * time.Second):
+		t.Fatal("Timed out waiting for trace")
+	}
+}
+
+// dummyAdmissionWebhookName is the webhook registered by
+// TestAPIServerTracingAdmission. It allows every request except creates for
+// a node whose name contains "denyme", which it rejects -- giving the test
+// a way to exercise both the allowed and denied admission span outcomes
+// against a single webhook registration.
+const dummyAdmissionWebhookName = "dummy-webhook.example.com"
+
+func newDummyAdmissionWebhookServer(t *testing.T) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response := &admissionv1.AdmissionResponse{
+			UID:     review.Request.UID,
+			Allowed: true,
+		}
+		if strings.Contains(review.Request.Name, "denyme") {
+			response.Allowed = false
+			response.Result = &metav1.Status{
+				Reason:  metav1.StatusReasonForbidden,
+				Message: "rejected by dummy-webhook.example.com",
+			}
+		}
+		review.Response = response
+
+		respBytes, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBytes)
+	}))
+}
+
+// registerDummyAdmissionWebhook points a ValidatingWebhookConfiguration at
+// webhookServer and waits for the API server to start invoking it, since
+// webhook registration takes effect asynchronously.
+func registerDummyAdmissionWebhook(t *testing.T, clientSet *client.Clientset, webhookServer *httptest.Server) {
+	t.Helper()
+	url := webhookServer.URL
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	webhookConfig := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "tracing-test-admission"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: dummyAdmissionWebhookName,
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					URL:      &url,
+					CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: webhookServer.Certificate().Raw}),
+				},
+				Rules: []admissionregistrationv1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"nodes"},
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+	if _, err := clientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(
+		context.Background(), webhookConfig, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wait.PollImmediate(100*time.Millisecond, 30*time.Second, func() (bool, error) {
+		_, err := clientSet.CoreV1().Nodes().Create(context.Background(),
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("denyme-probe-%d", time.Now().UnixNano())}},
+			metav1.CreateOptions{})
+		return apierrors.IsForbidden(err), nil
+	}); err != nil {
+		t.Fatalf("dummy admission webhook was never invoked: %v", err)
+	}
+}
+
+// TestAPIServerTracingAdmission asserts that each webhook invocation emits
+// its own span, as a child of the KubernetesAPI span, carrying the webhook
+// name, admission phase and allowed/denied outcome -- and that a denial adds
+// an event to the span.
+func TestAPIServerTracingAdmission(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, genericfeatures.APIServerTracing, true)()
+	// Listen for traces from the API Server before starting it, so the
+	// API Server will successfully connect right away during the test.
+	listener, err := net.Listen("tcp", "localhost:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write the configuration for tracing to a file
+	tracingConfigFile, err := os.CreateTemp("", "tracing-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tracingConfigFile.Name())
+
+	if err := os.WriteFile(tracingConfigFile.Name(), []byte(fmt.Sprintf(`
+apiVersion: apiserver.config.k8s.io/v1alpha1
+kind: TracingConfiguration
+samplingRatePerMillion: 1000000
+endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	fakeServer := &traceServer{t: t}
+	fakeServer.resetExpectations([]*spanExpectation{})
+	traceservice.RegisterTraceServiceServer(srv, fakeServer)
+
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	// Start the API Server with our tracing configuration
+	testServer := kubeapiservertesting.StartTestServerOrDie(t,
+		kubeapiservertesting.NewDefaultTestServerOptions(),
+		[]string{"--tracing-config-file=" + tracingConfigFile.Name()},
+		framework.SharedEtcd(),
+	)
+	defer testServer.TearDownFn()
+	clientSet, err := client.NewForConfig(testServer.ClientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhookServer := newDummyAdmissionWebhookServer(t)
+	defer webhookServer.Close()
+	registerDummyAdmissionWebhook(t, clientSet, webhookServer)
+
+	for _, tc := range []tracingTestCase{
+		{
+			desc: "create node admitted by webhook",
+			apiCall: func(c *client.Clientset) error {
+				_, err := c.CoreV1().Nodes().Create(context.Background(),
+					&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "admission-allowed"}}, metav1.CreateOptions{})
+				return err
+			},
+			expectedTrace: []*spanExpectation{
+				{name: "KubernetesAPI"},
+				{
+					name: "admit",
+					attributes: map[string]func(*commonv1.AnyValue) bool{
+						"k8s.admission.webhook.name": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == dummyAdmissionWebhookName
+						},
+						"k8s.admission.phase": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "validating"
+						},
+						"k8s.admission.allowed": func(v *commonv1.AnyValue) bool {
+							return v.GetBoolValue()
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "create node denied by webhook",
+			apiCall: func(c *client.Clientset) error {
+				_, err := c.CoreV1().Nodes().Create(context.Background(),
+					&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "admission-denyme"}}, metav1.CreateOptions{})
+				if err == nil {
+					return fmt.Errorf("expected dummy webhook to deny the request")
+				}
+				if !apierrors.IsForbidden(err) {
+					return fmt.Errorf("expected a Forbidden error from the dummy webhook, got: %w", err)
+				}
+				return nil
+			},
+			expectedTrace: []*spanExpectation{
+				{name: "KubernetesAPI"},
+				{
+					name: "admit",
+					attributes: map[string]func(*commonv1.AnyValue) bool{
+						"k8s.admission.webhook.name": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == dummyAdmissionWebhookName
+						},
+						"k8s.admission.phase": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "validating"
+						},
+						"k8s.admission.allowed": func(v *commonv1.AnyValue) bool {
+							return !v.GetBoolValue()
+						},
+					},
+					events: []string{"admission.rejected"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			fakeServer.resetExpectations(tc.expectedTrace)
+
+			if err := tc.apiCall(clientSet); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case <-fakeServer.traceFound:
+			case <-time.After(30 * time.Second):
+				t.Fatal("Timed out waiting for trace")
+			}
+		})
+	}
+}
+
+// TestAPIServerTracingTLS asserts that the API server can export spans to a
+// collector that requires TLS: it stands up the fake traceServer behind a
+// self-signed certificate, points the tracing configuration's caFile at it,
+// and confirms spans still arrive.
+func TestAPIServerTracingTLS(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, genericfeatures.APIServerTracing, true)()
+
+	// The endpoint below is the listener's IP address, so the certificate
+	// must carry the loopback IPs as well as the localhost DNS name.
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey("localhost",
+		[]net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile, err := os.CreateTemp("", "tracing-ca.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	if _, err := caFile.Write(certPEM); err != nil {
+		t.Fatal(err)
+	}
+	if err := caFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listen for traces from the API Server before starting it, so the
+	// API Server will successfully connect right away during the test.
+	listener, err := net.Listen("tcp", "localhost:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracingConfigFile, err := os.CreateTemp("", "tracing-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tracingConfigFile.Name())
+
+	if err := os.WriteFile(tracingConfigFile.Name(), []byte(fmt.Sprintf(`
+apiVersion: apiserver.config.k8s.io/v1alpha1
+kind: TracingConfiguration
+samplingRatePerMillion: 1000000
+endpoint: %s
+tls:
+  caFile: %s`, listener.Addr().String(), caFile.Name())), os.FileMode(0755)); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&tlsCert)))
+	fakeServer := &traceServer{t: t}
+	fakeServer.resetExpectations([]*spanExpectation{})
+	traceservice.RegisterTraceServiceServer(srv, fakeServer)
+
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	testServer := kubeapiservertesting.StartTestServerOrDie(t,
+		kubeapiservertesting.NewDefaultTestServerOptions(),
+		[]string{"--tracing-config-file=" + tracingConfigFile.Name()},
+		framework.SharedEtcd(),
+	)
+	defer testServer.TearDownFn()
+	clientSet, err := client.NewForConfig(testServer.ClientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeServer.resetExpectations([]*spanExpectation{
+		{name: "KubernetesAPI"},
+		{name: "etcdserverpb.KV/Txn", events: []string{"message"}},
+	})
+	_, err = clientSet.CoreV1().Nodes().Create(context.Background(),
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fake"}}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fakeServer.traceFound:
+	case <-time.After(30 * time.Second):
+		t.Fatal("Timed out waiting for trace over TLS")
+	}
+}
+
+// TestAPIServerTracingHeaders asserts that a configured OTLP exporter header
+// (e.g. a bearer token for collectors that require authentication) reaches
+// the collector in the gRPC metadata of an export request from the API server.
+func TestAPIServerTracingHeaders(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, genericfeatures.APIServerTracing, true)()
+
+	listener, err := net.Listen("tcp", "localhost:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracingConfigFile, err := os.CreateTemp("", "tracing-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tracingConfigFile.Name())
+
+	if err := os.WriteFile(tracingConfigFile.Name(), []byte(fmt.Sprintf(`
+apiVersion: apiserver.config.k8s.io/v1alpha1
+kind: TracingConfiguration
+samplingRatePerMillion: 1000000
+endpoint: %s
+headers:
+  Authorization: Bearer tracing-test-token`, listener.Addr().String())), os.FileMode(0755)); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	fakeServer := &traceServer{
+		t:               t,
+		wantHeaderKey:   "authorization",
+		wantHeaderValue: "Bearer tracing-test-token",
+	}
+	fakeServer.resetExpectations([]*spanExpectation{})
+	traceservice.RegisterTraceServiceServer(srv, fakeServer)
+
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	testServer := kubeapiservertesting.StartTestServerOrDie(t,
+		kubeapiservertesting.NewDefaultTestServerOptions(),
+		[]string{"--tracing-config-file=" + tracingConfigFile.Name()},
+		framework.SharedEtcd(),
+	)
+	defer testServer.TearDownFn()
+	clientSet, err := client.NewForConfig(testServer.ClientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeServer.resetExpectations([]*spanExpectation{{name: "KubernetesAPI"}})
+	_, err = clientSet.CoreV1().Nodes().Create(context.Background(),
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fake"}}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fakeServer.headerFound:
+	case <-time.After(30 * time.Second):
+		t.Fatal("Timed out waiting for configured header on the OTLP export request")
+	}
+}
+
+// TestAPIServerTracingOTLPHTTP exercises the OTLP/HTTP transport (protobuf
+// over POST to /v1/traces), which is what operators in hardened environments
+// typically expose instead of OTLP/gRPC. It runs the exact same scenarios,
+// and the same spanExpectation/traceExpectation machinery, as
+// TestAPIServerTracing above -- only the collector and the configured
+// protocol differ.
+func TestAPIServerTracingOTLPHTTP(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, genericfeatures.APIServerTracing, true)()
+
+	fakeCollector := &httpTraceCollector{t: t}
+	fakeCollector.resetExpectations(traceExpectation{})
+	collectorServer := httptest.NewServer(fakeCollector)
+	defer collectorServer.Close()
+
+	// Write the configuration for tracing to a file
+	tracingConfigFile, err := os.CreateTemp("", "tracing-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tracingConfigFile.Name())
+
+	if err := os.WriteFile(tracingConfigFile.Name(), []byte(fmt.Sprintf(`
+apiVersion: apiserver.config.k8s.io/v1alpha1
+kind: TracingConfiguration
+samplingRatePerMillion: 1000000
+protocol: http/protobuf
+endpoint: %s`, collectorServer.Listener.Addr().String())), os.FileMode(0755)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Start the API Server with our tracing configuration
+	testServer := kubeapiservertesting.StartTestServerOrDie(t,
+		kubeapiservertesting.NewDefaultTestServerOptions(),
+		[]string{"--tracing-config-file=" + tracingConfigFile.Name()},
+		framework.SharedEtcd(),
+	)
+	defer testServer.TearDownFn()
+	clientSet, err := client.NewForConfig(testServer.ClientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range apiServerTracingTestCases(clientSet) {
+		t.Run(tc.desc, func(t *testing.T) {
+			fakeCollector.resetExpectations(tc.expectedTrace)
+
+			// Make our call to the API server
+			if err := tc.apiCall(clientSet); err != nil {
+				t.Fatal(err)
+			}
+
+			// Wait for a span to be recorded from our request
+			select {
+			case <-fakeCollector.traceFound:
+			case <-time.After(30 * time.Second):
+				t.Fatal("Timed out waiting for trace")
+			}
+		})
+	}
+}
+
+// tracingTestCase is a single scenario exercised against a running API
+// server, along with the trace it is expected to produce.
+type tracingTestCase struct {
+	desc          string
+	apiCall       func(*client.Clientset) error
+	expectedTrace []*spanExpectation
+}
+
+// apiServerTracingTestCases builds the table of scenarios shared by
+// TestAPIServerTracing (OTLP/gRPC) and TestAPIServerTracingOTLPHTTP
+// (OTLP/HTTP), so both transports are held to the same expectations.
+func apiServerTracingTestCases(clientSet *client.Clientset) []tracingTestCase {
+	return []tracingTestCase{
 		{
 			desc: "create node",
 			apiCall: func(c *client.Clientset) error {
-				_, err = clientSet.CoreV1().Nodes().Create(context.Background(),
+				_, err := c.CoreV1().Nodes().Create(context.Background(),
 					&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fake"}}, metav1.CreateOptions{})
 				return err
 			},
@@ -182,6 +756,15 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 						"rpc.system": func(v *commonv1.AnyValue) bool {
 							return v.GetStringValue() == "grpc"
 						},
+						"k8s.resource": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "nodes"
+						},
+						"k8s.verb": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "create"
+						},
+						"k8s.namespace": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == ""
+						},
 					},
 					events: []string{"message"},
 				},
@@ -191,7 +774,7 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 			desc: "get node",
 			apiCall: func(c *client.Clientset) error {
 				// This depends on the "create node" step having completed successfully
-				_, err = clientSet.CoreV1().Nodes().Get(context.Background(), "fake", metav1.GetOptions{})
+				_, err := c.CoreV1().Nodes().Get(context.Background(), "fake", metav1.GetOptions{})
 				return err
 			},
 			expectedTrace: []*spanExpectation{
@@ -215,6 +798,15 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 						"rpc.system": func(v *commonv1.AnyValue) bool {
 							return v.GetStringValue() == "grpc"
 						},
+						"k8s.resource": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "nodes"
+						},
+						"k8s.verb": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "get"
+						},
+						"k8s.namespace": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == ""
+						},
 					},
 					events: []string{"message"},
 				},
@@ -223,7 +815,7 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 		{
 			desc: "list nodes",
 			apiCall: func(c *client.Clientset) error {
-				_, err = clientSet.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+				_, err := c.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
 				return err
 			},
 			expectedTrace: []*spanExpectation{
@@ -247,6 +839,18 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 						"rpc.system": func(v *commonv1.AnyValue) bool {
 							return v.GetStringValue() == "grpc"
 						},
+						"k8s.resource": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "nodes"
+						},
+						"k8s.verb": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "list"
+						},
+						"k8s.namespace": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == ""
+						},
+						"k8s.object.count": func(v *commonv1.AnyValue) bool {
+							return v.GetIntValue() >= 1
+						},
 					},
 					events: []string{"message"},
 				},
@@ -256,7 +860,7 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 			desc: "update node",
 			apiCall: func(c *client.Clientset) error {
 				// This depends on the "create node" step having completed successfully
-				_, err = clientSet.CoreV1().Nodes().Update(context.Background(),
+				_, err := c.CoreV1().Nodes().Update(context.Background(),
 					&v1.Node{ObjectMeta: metav1.ObjectMeta{
 						Name:        "fake",
 						Annotations: map[string]string{"foo": "bar"},
@@ -284,6 +888,15 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 						"rpc.system": func(v *commonv1.AnyValue) bool {
 							return v.GetStringValue() == "grpc"
 						},
+						"k8s.resource": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "nodes"
+						},
+						"k8s.verb": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "update"
+						},
+						"k8s.namespace": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == ""
+						},
 					},
 					events: []string{"message"},
 				},
@@ -315,7 +928,7 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 				if err != nil {
 					return err
 				}
-				_, err = clientSet.CoreV1().Nodes().Patch(context.Background(), "fake", types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+				_, err = c.CoreV1().Nodes().Patch(context.Background(), "fake", types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
 				return err
 			},
 			expectedTrace: []*spanExpectation{
@@ -339,6 +952,15 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 						"rpc.system": func(v *commonv1.AnyValue) bool {
 							return v.GetStringValue() == "grpc"
 						},
+						"k8s.resource": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "nodes"
+						},
+						"k8s.verb": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "patch"
+						},
+						"k8s.namespace": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == ""
+						},
 					},
 					events: []string{"message"},
 				},
@@ -348,7 +970,7 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 			desc: "delete node",
 			apiCall: func(c *client.Clientset) error {
 				// This depends on the "create node" step having completed successfully
-				return clientSet.CoreV1().Nodes().Delete(context.Background(), "fake", metav1.DeleteOptions{})
+				return c.CoreV1().Nodes().Delete(context.Background(), "fake", metav1.DeleteOptions{})
 			},
 			expectedTrace: []*spanExpectation{
 				{
@@ -371,27 +993,20 @@ endpoint: %s`, listener.Addr().String())), os.FileMode(0755)); err != nil {
 						"rpc.system": func(v *commonv1.AnyValue) bool {
 							return v.GetStringValue() == "grpc"
 						},
+						"k8s.resource": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "nodes"
+						},
+						"k8s.verb": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == "delete"
+						},
+						"k8s.namespace": func(v *commonv1.AnyValue) bool {
+							return v.GetStringValue() == ""
+						},
 					},
 					events: []string{"message"},
 				},
 			},
 		},
-	} {
-		t.Run(tc.desc, func(t *testing.T) {
-			fakeServer.resetExpectations(tc.expectedTrace)
-
-			// Make our call to the API server
-			if err := tc.apiCall(clientSet); err != nil {
-				t.Fatal(err)
-			}
-
-			// Wait for a span to be recorded from our request
-			select {
-			case <-fakeServer.traceFound:
-			case <-time.After(30 * time.Second):
-				t.Fatal("Timed out waiting for trace")
-			}
-		})
 	}
 }
 
@@ -404,6 +1019,14 @@ type traceServer struct {
 	lock         sync.Mutex
 	traceFound   chan struct{}
 	expectations traceExpectation
+	// wantHeaderKey/wantHeaderValue, if wantHeaderKey is non-empty, cause
+	// Export to additionally watch the incoming gRPC metadata for a
+	// matching entry (e.g. to assert that a configured OTLP exporter
+	// header, such as an Authorization bearer token, reaches the
+	// collector) and signal headerFound once it does.
+	wantHeaderKey   string
+	wantHeaderValue string
+	headerFound     chan struct{}
 }
 
 func (t *traceServer) Export(ctx context.Context, req *traceservice.ExportTraceServiceRequest) (*traceservice.ExportTraceServiceResponse, error) {
@@ -420,6 +1043,22 @@ func (t *traceServer) Export(ctx context.Context, req *traceservice.ExportTraceS
 			close(t.traceFound)
 		}
 	}
+
+	if t.wantHeaderKey != "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			for _, v := range md.Get(t.wantHeaderKey) {
+				if v == t.wantHeaderValue {
+					select {
+					case <-t.headerFound:
+						// headerFound is already closed
+					default:
+						close(t.headerFound)
+					}
+					break
+				}
+			}
+		}
+	}
 	return &traceservice.ExportTraceServiceResponse{}, nil
 }
 
@@ -429,9 +1068,69 @@ func (t *traceServer) resetExpectations(newExpectations traceExpectation) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 	t.traceFound = make(chan struct{})
+	t.headerFound = make(chan struct{})
 	t.expectations = newExpectations
 }
 
+// httpTraceCollector is the OTLP/HTTP counterpart of traceServer: instead of
+// a gRPC TraceServiceServer, it is an http.Handler that decodes
+// ExportTraceServiceRequest messages POSTed to /v1/traces as protobuf, and
+// feeds them through the same spanExpectation/traceExpectation machinery.
+type httpTraceCollector struct {
+	t *testing.T
+	// the lock guards the per-scenario state below
+	lock         sync.Mutex
+	traceFound   chan struct{}
+	expectations traceExpectation
+}
+
+func (h *httpTraceCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &traceservice.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.expectations.update(req)
+	// if all expectations are met, notify the test scenario by closing traceFound
+	if h.expectations.met() {
+		select {
+		case <-h.traceFound:
+			// traceFound is already closed
+		default:
+			close(h.traceFound)
+		}
+	}
+
+	respBytes, err := proto.Marshal(&traceservice.ExportTraceServiceResponse{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(respBytes)
+}
+
+// resetExpectations is used by a new test scenario to set new expectations
+// for the HTTP collector.
+func (h *httpTraceCollector) resetExpectations(newExpectations traceExpectation) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.traceFound = make(chan struct{})
+	h.expectations = newExpectations
+}
+
 // traceExpectation is an expectation for an entire trace
 type traceExpectation []*spanExpectation
 
@@ -487,7 +1186,11 @@ func (t traceExpectation) updateForSpan(span *tracev1.Span) {
 		if !spanExpectation.events.matches(span.GetEvents()) {
 			continue
 		}
-		t[i].metTraceIDs = append(spanExpectation.metTraceIDs, hex.EncodeToString(span.TraceId[:]))
+		traceID := hex.EncodeToString(span.TraceId[:])
+		if spanExpectation.parentTraceID != "" && spanExpectation.parentTraceID != traceID {
+			continue
+		}
+		t[i].metTraceIDs = append(spanExpectation.metTraceIDs, traceID)
 	}
 
 }
@@ -497,6 +1200,10 @@ type spanExpectation struct {
 	name       string
 	attributes attributeExpectation
 	events     eventExpectation
+	// parentTraceID, if set, requires the span to belong to this trace ID
+	// (hex-encoded), e.g. to assert that a client-supplied traceparent was
+	// propagated instead of the server minting a new root trace.
+	parentTraceID string
 	// For each trace ID that meets this expectation, record it here.
 	// This way, we can ensure that all spans that should be in the same trace have the same trace ID
 	metTraceIDs []string